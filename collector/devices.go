@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var deviceNamesOnce sync.Once
+var deviceNames map[string]string
+
+// resolveDevice maps a block device's major:minor pair to its name
+// (e.g. "sda") using /proc/partitions, which is read once on the first
+// call and cached, falling back to the numeric pair itself when the
+// device can't be resolved.
+func resolveDevice(major, minor uint64) string {
+	deviceNamesOnce.Do(loadDeviceNames)
+
+	key := deviceKey(major, minor)
+	if name, ok := deviceNames[key]; ok {
+		return name
+	}
+	return key
+}
+
+func deviceKey(major, minor uint64) string {
+	return fmt.Sprintf("%d:%d", major, minor)
+}
+
+func loadDeviceNames() {
+	deviceNames = map[string]string{}
+
+	f, err := os.Open("/proc/partitions")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	// skip the header line and the blank line that follows it
+	scanner.Scan()
+	scanner.Scan()
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+
+		major, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		minor, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		deviceNames[deviceKey(major, minor)] = fields[3]
+	}
+}
@@ -0,0 +1,118 @@
+package collector
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+var containerInclude = splitPatterns(Getenv("COLLECTD_DOCKER_CONTAINER_INCLUDE", ""))
+var containerExclude = splitPatterns(Getenv("COLLECTD_DOCKER_CONTAINER_EXCLUDE", ""))
+var labelInclude = splitPatterns(Getenv("COLLECTD_DOCKER_LABEL_INCLUDE", ""))
+var labelExclude = splitPatterns(Getenv("COLLECTD_DOCKER_LABEL_EXCLUDE", ""))
+
+// defaultFilter is built from the COLLECTD_DOCKER_CONTAINER_* and
+// COLLECTD_DOCKER_LABEL_* env vars and is consulted by NewMonitor.
+var defaultFilter = NewFilter(containerInclude, containerExclude, labelInclude, labelExclude)
+
+// Filter decides whether a container should be monitored based on
+// glob patterns matched against its name and its `key=value` labels.
+// An empty include list allows everything; an empty exclude list denies
+// nothing. Exclude always takes precedence over include.
+type Filter struct {
+	containerInclude []string
+	containerExclude []string
+	labelInclude     []string
+	labelExclude     []string
+}
+
+// NewFilter builds a Filter from already-split glob pattern lists (see
+// splitPatterns for turning a comma-separated env var into one).
+func NewFilter(containerInclude, containerExclude, labelInclude, labelExclude []string) *Filter {
+	return &Filter{
+		containerInclude: containerInclude,
+		containerExclude: containerExclude,
+		labelInclude:     labelInclude,
+		labelExclude:     labelExclude,
+	}
+}
+
+// splitPatterns splits a comma-separated env var into a trimmed pattern
+// list, dropping empty entries.
+func splitPatterns(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	var patterns []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// Allowed reports whether the given container name and labels pass the
+// filter. Exclude patterns are checked first and always win; an empty
+// include list is treated as "allow all" once exclude has been cleared.
+func (f *Filter) Allowed(name string, labels map[string]string) bool {
+	if matchAny(f.containerExclude, name) {
+		return false
+	}
+	if matchAnyLabel(f.labelExclude, labels) {
+		return false
+	}
+
+	if len(f.containerInclude) > 0 && !matchAny(f.containerInclude, name) {
+		return false
+	}
+	if len(f.labelInclude) > 0 && !matchAnyLabel(f.labelInclude, labels) {
+		return false
+	}
+
+	return true
+}
+
+// AllowedContainer is a convenience wrapper around Allowed for a
+// *docker.Container, extracting its name and labels.
+func (f *Filter) AllowedContainer(c *docker.Container) bool {
+	return f.Allowed(strings.TrimPrefix(c.Name, "/"), c.Config.Labels)
+}
+
+// AllowedAPIContainer is the same as AllowedContainer but for the
+// lightweight docker.APIContainers returned by ListContainers, so the
+// top-level listing loop can skip InspectContainer calls entirely for
+// containers that the filter would reject anyway.
+func (f *Filter) AllowedAPIContainer(c docker.APIContainers) bool {
+	var name string
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+	return f.Allowed(name, c.Labels)
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyLabel(patterns []string, labels map[string]string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	for k, v := range labels {
+		pair := k + "=" + v
+		if matchAny(patterns, pair) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package collector
+
+import "testing"
+
+func TestFilterEmptyDefaults(t *testing.T) {
+	f := NewFilter(nil, nil, nil, nil)
+
+	if !f.Allowed("anything", nil) {
+		t.Error("empty include should allow all container names")
+	}
+	if !f.Allowed("anything", map[string]string{"foo": "bar"}) {
+		t.Error("empty include should allow all labels")
+	}
+}
+
+func TestFilterContainerGlob(t *testing.T) {
+	f := NewFilter([]string{"web-*"}, nil, nil, nil)
+
+	if !f.Allowed("web-1", nil) {
+		t.Error("expected web-1 to match web-*")
+	}
+	if f.Allowed("worker-1", nil) {
+		t.Error("expected worker-1 to not match web-*")
+	}
+}
+
+func TestFilterContainerExclude(t *testing.T) {
+	f := NewFilter(nil, []string{"worker-?"}, nil, nil)
+
+	if f.Allowed("worker-1", nil) {
+		t.Error("expected worker-1 to be excluded by worker-?")
+	}
+	if !f.Allowed("web-1", nil) {
+		t.Error("expected web-1 to remain allowed")
+	}
+}
+
+func TestFilterExcludeOverridesInclude(t *testing.T) {
+	f := NewFilter([]string{"web-*"}, []string{"web-2"}, nil, nil)
+
+	if !f.Allowed("web-1", nil) {
+		t.Error("expected web-1 to be allowed by include")
+	}
+	if f.Allowed("web-2", nil) {
+		t.Error("expected web-2 to be excluded even though it matches include")
+	}
+}
+
+func TestFilterLabelGlob(t *testing.T) {
+	f := NewFilter(nil, nil, []string{"env=prod*"}, nil)
+
+	if !f.Allowed("c", map[string]string{"env": "production"}) {
+		t.Error("expected env=production to match env=prod*")
+	}
+	if f.Allowed("c", map[string]string{"env": "staging"}) {
+		t.Error("expected env=staging to not match env=prod*")
+	}
+}
+
+func TestFilterLabelExcludeOverridesInclude(t *testing.T) {
+	f := NewFilter(nil, nil, []string{"env=*"}, []string{"tier=internal"})
+
+	if f.Allowed("c", map[string]string{"env": "prod", "tier": "internal"}) {
+		t.Error("expected tier=internal exclude to win over env=* include")
+	}
+	if !f.Allowed("c", map[string]string{"env": "prod"}) {
+		t.Error("expected env=prod to be allowed")
+	}
+}
+
+func TestFilterCharacterClass(t *testing.T) {
+	f := NewFilter([]string{"web-[12]"}, nil, nil, nil)
+
+	if !f.Allowed("web-1", nil) {
+		t.Error("expected web-1 to match web-[12]")
+	}
+	if f.Allowed("web-3", nil) {
+		t.Error("expected web-3 to not match web-[12]")
+	}
+}
@@ -0,0 +1,277 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"log"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+var collectLogs = Getenv("COLLECTD_DOCKER_COLLECT_LOGS", "") == "1"
+
+// Note: this package originally also read a COLLECTD_DOCKER_LOG_TTY_CONTAINERS
+// env var to opt in to non-multiplexed parsing for TTY containers. That knob
+// was removed: whether a container's log stream is multiplexed is a
+// server-side fact entirely determined by container.Config.Tty, not
+// something a client-side flag can correctly override, so handle() now
+// derives it from m.tty alone. The env var is no longer read.
+
+// LogDockerClient is the subset of docker.Client used by LogMonitor.
+type LogDockerClient interface {
+	InspectContainer(id string) (*docker.Container, error)
+	Logs(opts docker.LogsOptions) error
+}
+
+// LogEntry is a single line read from a monitored container's stdout or
+// stderr, tagged the same way as Stats so it can be correlated with the
+// metrics for the same app/task.
+type LogEntry struct {
+	Tags      map[string]string
+	Stream    string
+	Timestamp time.Time
+	Line      string
+}
+
+// LogMonitor streams a container's stdout/stderr via the Docker logs
+// API, analogous to how Monitor streams its stats.
+type LogMonitor struct {
+	client MonitorDockerClient
+	id     string
+	tags   map[string]string
+	tty    bool
+	since  int64
+
+	// lastSeen holds the newest timestamp emitted per stream, used to
+	// drop lines that a resumed stream sends again.
+	lastSeen map[string]time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewLogMonitor creates a log monitor for the given container, reusing
+// the same app/task tag extraction as NewMonitor.
+func NewLogMonitor(c MonitorDockerClient, id string) (*LogMonitor, error) {
+	if !collectLogs {
+		return nil, ErrNoNeedToMonitor
+	}
+
+	container, err := c.InspectContainer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !defaultFilter.AllowedContainer(container) {
+		return nil, ErrNoNeedToMonitor
+	}
+
+	app := extractApp(container)
+	if app == "" {
+		return nil, ErrNoNeedToMonitor
+	}
+
+	tags := map[string]string{}
+	extractTagsFromApp(tags, app)
+	extractTagsFromTask(tags, extractTask(container))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &LogMonitor{
+		client: c,
+		id:     container.ID,
+		tags:   tags,
+		tty:    container.Config.Tty,
+		ctx:    ctx,
+		cancel: cancel,
+
+		lastSeen: map[string]time.Time{},
+	}, nil
+}
+
+// Stop ends the log stream, causing handle to return.
+func (m *LogMonitor) Stop() {
+	m.cancel()
+}
+
+// run streams logs until Stop is called, reconnecting with the last
+// seen timestamp after a transient daemon error so the resumed stream
+// does not replay lines the caller already received.
+func (m *LogMonitor) run(ch chan<- LogEntry) {
+	for {
+		if m.ctx.Err() != nil {
+			return
+		}
+
+		if err := m.handle(ch); err != nil {
+			log.Printf("Log stream for %s ended: %s", m.id, err)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (m *LogMonitor) handle(ch chan<- LogEntry) error {
+	logsClient, ok := m.client.(LogDockerClient)
+	if !ok {
+		return errLogsUnsupported
+	}
+
+	emit := func(stream string, ts time.Time, line string) {
+		if !ts.IsZero() {
+			// Since has one-second granularity, so a resumed stream
+			// repeats the lines of the last-seen second; skip those.
+			if last, ok := m.lastSeen[stream]; ok && !ts.After(last) {
+				return
+			}
+			m.lastSeen[stream] = ts
+			m.since = ts.Unix()
+		}
+		ch <- LogEntry{
+			Tags:      m.tags,
+			Stream:    stream,
+			Timestamp: ts,
+			Line:      line,
+		}
+	}
+
+	// Whether the log stream is multiplexed is a server-side fact
+	// determined entirely by whether the container was created with a
+	// TTY, not something a client-side flag can override.
+	rawTTY := m.tty
+	out := newDemuxWriter(emit, rawTTY)
+
+	opts := docker.LogsOptions{
+		Context:      m.ctx,
+		Container:    m.id,
+		OutputStream: out,
+		ErrorStream:  out,
+		Stdout:       true,
+		Stderr:       true,
+		Follow:       true,
+		Timestamps:   true,
+		Since:        m.since,
+		RawTerminal:  rawTTY,
+	}
+
+	err := logsClient.Logs(opts)
+	out.flush()
+	return err
+}
+
+var errLogsUnsupported = &logsUnsupportedError{}
+
+type logsUnsupportedError struct{}
+
+func (e *logsUnsupportedError) Error() string {
+	return "docker client does not support the Logs API"
+}
+
+const dockerStreamHeaderSize = 8
+
+// demuxWriter splits the Docker multiplexed log stream (an 8-byte
+// header [STREAM_TYPE, 0, 0, 0, SIZE_BE32] followed by SIZE bytes of
+// payload for each frame) into per-stream, line-buffered output. When
+// raw is true (non-multiplexed TTY containers) frames are treated as
+// plain stdout text instead.
+type demuxWriter struct {
+	emit func(stream string, ts time.Time, line string)
+	raw  bool
+
+	buf    bytes.Buffer
+	stdout bytes.Buffer
+	stderr bytes.Buffer
+}
+
+func newDemuxWriter(emit func(stream string, ts time.Time, line string), raw bool) *demuxWriter {
+	return &demuxWriter{emit: emit, raw: raw}
+}
+
+func (w *demuxWriter) Write(p []byte) (int, error) {
+	if w.raw {
+		return w.writeLines(&w.stdout, "stdout", p)
+	}
+
+	w.buf.Write(p)
+	for {
+		if w.buf.Len() < dockerStreamHeaderSize {
+			break
+		}
+
+		header := w.buf.Bytes()[:dockerStreamHeaderSize]
+		size := int(binary.BigEndian.Uint32(header[4:8]))
+		if w.buf.Len() < dockerStreamHeaderSize+size {
+			break
+		}
+
+		streamType := header[0]
+		frame := make([]byte, size)
+		copy(frame, w.buf.Bytes()[dockerStreamHeaderSize:dockerStreamHeaderSize+size])
+		w.buf.Next(dockerStreamHeaderSize + size)
+
+		switch streamType {
+		case 2:
+			w.writeLines(&w.stderr, "stderr", frame)
+		default:
+			w.writeLines(&w.stdout, "stdout", frame)
+		}
+	}
+
+	return len(p), nil
+}
+
+func (w *demuxWriter) writeLines(buf *bytes.Buffer, stream string, p []byte) (int, error) {
+	buf.Write(p)
+	for {
+		line, err := buf.ReadString('\n')
+		if err != nil {
+			// incomplete line: put it back for the next write
+			buf.WriteString(line)
+			break
+		}
+		w.emitLine(stream, line[:len(line)-1])
+	}
+	return len(p), nil
+}
+
+func (w *demuxWriter) emitLine(stream, line string) {
+	ts, rest := splitTimestamp(line)
+	w.emit(stream, ts, rest)
+}
+
+func (w *demuxWriter) flush() {
+	if w.stdout.Len() > 0 {
+		w.emitLine("stdout", w.stdout.String())
+		w.stdout.Reset()
+	}
+	if w.stderr.Len() > 0 {
+		w.emitLine("stderr", w.stderr.String())
+		w.stderr.Reset()
+	}
+}
+
+// splitTimestamp strips the RFC3339Nano timestamp prefix that Docker
+// adds when Timestamps is requested, returning the zero time if the
+// line is unexpectedly missing one.
+func splitTimestamp(line string) (time.Time, string) {
+	idx := bytes.IndexByte([]byte(line), ' ')
+	if idx < 0 {
+		return time.Time{}, line
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, line[:idx])
+	if err != nil {
+		return time.Time{}, line
+	}
+
+	return ts, line[idx+1:]
+}
+
+var _ io.Writer = (*demuxWriter)(nil)
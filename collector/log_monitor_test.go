@@ -0,0 +1,64 @@
+package collector
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func frame(streamType byte, payload string) []byte {
+	header := make([]byte, dockerStreamHeaderSize)
+	header[0] = streamType
+	binary.BigEndian.PutUint32(header[4:8], uint32(len(payload)))
+	return append(header, []byte(payload)...)
+}
+
+func TestDemuxWriterSplitsStreams(t *testing.T) {
+	var got []LogEntry
+	w := newDemuxWriter(func(stream string, ts time.Time, line string) {
+		got = append(got, LogEntry{Stream: stream, Timestamp: ts, Line: line})
+	}, false)
+
+	ts := "2026-07-27T12:00:00.000000000Z"
+	w.Write(frame(1, ts+" hello from stdout\n"))
+	w.Write(frame(2, ts+" hello from stderr\n"))
+	w.flush()
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(got))
+	}
+	if got[0].Stream != "stdout" || got[0].Line != "hello from stdout" {
+		t.Errorf("unexpected stdout entry: %+v", got[0])
+	}
+	if got[1].Stream != "stderr" || got[1].Line != "hello from stderr" {
+		t.Errorf("unexpected stderr entry: %+v", got[1])
+	}
+}
+
+func TestDemuxWriterHandlesPartialFrames(t *testing.T) {
+	var got []LogEntry
+	w := newDemuxWriter(func(stream string, ts time.Time, line string) {
+		got = append(got, LogEntry{Stream: stream, Line: line})
+	}, false)
+
+	f := frame(1, "2026-07-27T12:00:00.000000000Z split line\n")
+	w.Write(f[:5])
+	w.Write(f[5:])
+
+	if len(got) != 1 || got[0].Line != "split line" {
+		t.Fatalf("expected 1 reassembled line, got %+v", got)
+	}
+}
+
+func TestDemuxWriterRawTTY(t *testing.T) {
+	var got []LogEntry
+	w := newDemuxWriter(func(stream string, ts time.Time, line string) {
+		got = append(got, LogEntry{Stream: stream, Line: line})
+	}, true)
+
+	w.Write([]byte("2026-07-27T12:00:00.000000000Z plain tty line\n"))
+
+	if len(got) != 1 || got[0].Stream != "stdout" || got[0].Line != "plain tty line" {
+		t.Fatalf("unexpected tty entry: %+v", got)
+	}
+}
@@ -53,6 +53,7 @@ type Monitor struct {
 	task     string
 	tags     map[string]string
 	interval int
+	done     chan bool
 }
 
 // NewMonitor creates new monitor with specified docker client,
@@ -63,6 +64,11 @@ func NewMonitor(c MonitorDockerClient, id string, interval int) (*Monitor, error
 	if err != nil {
 		return nil, err
 	}
+	if !defaultFilter.AllowedContainer(container) {
+		log.Printf("Filtered out %s %s\n", id, container.Name)
+		return nil, ErrNoNeedToMonitor
+	}
+
 	app := extractApp(container)
 
 	if app == "" {
@@ -82,9 +88,15 @@ func NewMonitor(c MonitorDockerClient, id string, interval int) (*Monitor, error
 		task:     task,
 		tags:     tags,
 		interval: interval,
+		done:     make(chan bool),
 	}, nil
 }
 
+// Stop ends the monitor's Stats stream, causing handle to return.
+func (m *Monitor) Stop() {
+	close(m.done)
+}
+
 func (m *Monitor) handle(ch chan<- Stats) error {
 	in := make(chan *docker.Stats)
 
@@ -96,9 +108,8 @@ func (m *Monitor) handle(ch chan<- Stats) error {
 				continue
 			}
 
-			ch <- Stats{
-				Tags:  m.tags,
-				Stats: *s,
+			for _, out := range expandStats(m.tags, s) {
+				ch <- out
 			}
 
 			i++
@@ -109,6 +120,7 @@ func (m *Monitor) handle(ch chan<- Stats) error {
 		ID:     m.id,
 		Stats:  in,
 		Stream: true,
+		Done:   m.done,
 	})
 }
 
@@ -0,0 +1,131 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Sink is an output destination for collected Stats and LogEntry
+// samples. The Monitor/Watcher/LogMonitor subsystems know nothing about
+// any particular sink; Dispatcher fans samples out to the sinks it was
+// created with, such as those NewSinks builds from a spec string. This
+// package reads no environment variable to choose the sinks; the caller
+// supplies the spec.
+type Sink interface {
+	Emit(Stats) error
+	EmitLog(LogEntry) error
+	Close() error
+}
+
+// NewSinks builds the sinks named in spec, a comma-separated list such
+// as "collectd" or "statsd,prometheus".
+func NewSinks(spec string) ([]Sink, error) {
+	var sinks []Sink
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		sink, err := newSink(name)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func newSink(name string) (Sink, error) {
+	switch name {
+	case "collectd":
+		return NewCollectdSink(), nil
+	case "statsd":
+		return NewStatsDSink(Getenv("COLLECTD_DOCKER_STATSD_ADDR", "127.0.0.1:8125"))
+	case "prometheus":
+		return NewPrometheusSink(Getenv("COLLECTD_DOCKER_PROMETHEUS_ADDR", ":9103"))
+	default:
+		return nil, fmt.Errorf("unknown sink %q", name)
+	}
+}
+
+// Dispatcher reads Stats and LogEntry samples off their channels and
+// fans each one out to every configured sink concurrently.
+type Dispatcher struct {
+	sinks []Sink
+}
+
+// NewDispatcher creates a Dispatcher for the given sinks.
+func NewDispatcher(sinks []Sink) *Dispatcher {
+	return &Dispatcher{sinks: sinks}
+}
+
+// Run dispatches samples until ctx is cancelled or both channels close,
+// then closes every sink.
+func (d *Dispatcher) Run(ctx context.Context, stats <-chan Stats, logs <-chan LogEntry) {
+	defer d.closeAll()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s, ok := <-stats:
+			if !ok {
+				stats = nil
+				if logs == nil {
+					return
+				}
+				continue
+			}
+			d.emit(s)
+		case l, ok := <-logs:
+			if !ok {
+				logs = nil
+				if stats == nil {
+					return
+				}
+				continue
+			}
+			d.emitLog(l)
+		}
+	}
+}
+
+func (d *Dispatcher) emit(s Stats) {
+	var wg sync.WaitGroup
+	for _, sink := range d.sinks {
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			if err := sink.Emit(s); err != nil {
+				log.Printf("Sink emit error: %s", err)
+			}
+		}(sink)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) emitLog(l LogEntry) {
+	var wg sync.WaitGroup
+	for _, sink := range d.sinks {
+		wg.Add(1)
+		go func(sink Sink) {
+			defer wg.Done()
+			if err := sink.EmitLog(l); err != nil {
+				log.Printf("Sink emit log error: %s", err)
+			}
+		}(sink)
+	}
+	wg.Wait()
+}
+
+func (d *Dispatcher) closeAll() {
+	for _, sink := range d.sinks {
+		if err := sink.Close(); err != nil {
+			log.Printf("Sink close error: %s", err)
+		}
+	}
+}
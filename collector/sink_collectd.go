@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// CollectdSink formats Stats as collectd exec plugin PUTVAL lines,
+// written to w (stdout by default, matching how collectd invokes exec
+// plugins and reads their stdout).
+type CollectdSink struct {
+	w        io.Writer
+	hostname string
+	interval int
+}
+
+// NewCollectdSink creates a CollectdSink writing to stdout.
+func NewCollectdSink() *CollectdSink {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	return &CollectdSink{
+		w:        os.Stdout,
+		hostname: hostname,
+		interval: 10,
+	}
+}
+
+// Emit writes one PUTVAL line per metric in s.
+func (c *CollectdSink) Emit(s Stats) error {
+	instance := collectdInstance(s.Tags)
+	now := time.Now().Unix()
+
+	for name, value := range s.Metrics() {
+		_, err := fmt.Fprintf(c.w, "PUTVAL \"%s/docker-%s/gauge-%s\" interval=%d %d:%g\n",
+			c.hostname, instance, sanitizeCollectdName(name), c.interval, now, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmitLog is a no-op: the collectd exec protocol has no log transport.
+func (c *CollectdSink) EmitLog(LogEntry) error {
+	return nil
+}
+
+// Close is a no-op: stdout is owned by the process, not the sink.
+func (c *CollectdSink) Close() error {
+	return nil
+}
+
+func collectdInstance(tags map[string]string) string {
+	instance := tags["app"] + "." + tags["task"]
+	if device, ok := tags["device"]; ok {
+		instance += "." + device
+	}
+	if iface, ok := tags["interface"]; ok {
+		instance += "." + iface
+	}
+	return sanitizeCollectdName(instance)
+}
+
+func sanitizeCollectdName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+var _ Sink = (*CollectdSink)(nil)
@@ -0,0 +1,125 @@
+package collector
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// prometheusLabelNames is the fixed label schema used for every metric
+// family. Prometheus requires every series registered under a given
+// metric name to share the same label set, but a Stats tag map's shape
+// varies by fan-out kind (none, "device", or "interface") and its
+// dynamically-named "groupN"/"taskN" keys vary with Marathon app-path
+// depth, so only this well-known subset is surfaced as labels; any tag
+// not in this list is dropped for this sink.
+var prometheusLabelNames = []string{"app", "app_id", "task", "group", "device", "interface"}
+
+// PrometheusSink serves Stats on a /metrics HTTP endpoint, maintaining
+// one prometheus.GaugeVec per metric family.
+type PrometheusSink struct {
+	mu       sync.Mutex
+	registry *prometheus.Registry
+	families map[string]*prometheus.GaugeVec
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewPrometheusSink starts an HTTP server on addr (e.g. ":9103") serving
+// /metrics.
+func NewPrometheusSink(addr string) (*PrometheusSink, error) {
+	registry := prometheus.NewRegistry()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &PrometheusSink{
+		registry: registry,
+		families: map[string]*prometheus.GaugeVec{},
+		listener: listener,
+		server:   &http.Server{Handler: mux},
+	}
+
+	go func() {
+		if err := sink.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Prometheus sink server error: %s", err)
+		}
+	}()
+
+	return sink, nil
+}
+
+// Emit records one gauge per metric in s, labeled with the fixed
+// prometheusLabelNames subset of s.Tags.
+func (p *PrometheusSink) Emit(s Stats) error {
+	labels := prometheusLabels(s.Tags)
+
+	for name, value := range s.Metrics() {
+		gv, err := p.familyFor("container_" + sanitizePrometheusName(name))
+		if err != nil {
+			return err
+		}
+		gv.With(labels).Set(value)
+	}
+	return nil
+}
+
+// EmitLog is a no-op: Prometheus is a metrics sink, not a log sink.
+func (p *PrometheusSink) EmitLog(LogEntry) error {
+	return nil
+}
+
+// Close stops the HTTP server.
+func (p *PrometheusSink) Close() error {
+	return p.server.Close()
+}
+
+func (p *PrometheusSink) familyFor(name string) (*prometheus.GaugeVec, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if gv, ok := p.families[name]; ok {
+		return gv, nil
+	}
+
+	gv := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name}, prometheusLabelNames)
+	if err := p.registry.Register(gv); err != nil {
+		return nil, err
+	}
+
+	p.families[name] = gv
+	return gv, nil
+}
+
+// prometheusLabels maps a Stats tag set onto the fixed Prometheus label
+// schema, defaulting any label absent from tags to "".
+func prometheusLabels(tags map[string]string) prometheus.Labels {
+	labels := make(prometheus.Labels, len(prometheusLabelNames))
+	for _, name := range prometheusLabelNames {
+		labels[name] = tags[name]
+	}
+	return labels
+}
+
+func sanitizePrometheusName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+var _ Sink = (*PrometheusSink)(nil)
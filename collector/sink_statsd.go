@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// StatsDSink emits Stats as DogStatsD gauges over UDP, rendering
+// Stats.Tags as "#key:value" tags.
+type StatsDSink struct {
+	conn net.Conn
+}
+
+// NewStatsDSink dials a UDP connection to addr (e.g. "127.0.0.1:8125").
+func NewStatsDSink(addr string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &StatsDSink{conn: conn}, nil
+}
+
+// Emit writes one DogStatsD gauge line per metric in s.
+func (s *StatsDSink) Emit(stat Stats) error {
+	tags := statsDTags(stat.Tags)
+
+	for name, value := range stat.Metrics() {
+		line := fmt.Sprintf("container.%s:%g|g|#%s\n", name, value, tags)
+		if _, err := s.conn.Write([]byte(line)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EmitLog is a no-op: StatsD has no log transport.
+func (s *StatsDSink) EmitLog(LogEntry) error {
+	return nil
+}
+
+// Close closes the underlying UDP connection.
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+func statsDTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+":"+tags[k])
+	}
+	return strings.Join(parts, ",")
+}
+
+var _ Sink = (*StatsDSink)(nil)
@@ -0,0 +1,124 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+type recordingSink struct {
+	mu    sync.Mutex
+	stats []Stats
+	logs  []LogEntry
+}
+
+func (r *recordingSink) Emit(s Stats) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = append(r.stats, s)
+	return nil
+}
+
+func (r *recordingSink) EmitLog(l LogEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, l)
+	return nil
+}
+
+func (r *recordingSink) Close() error { return nil }
+
+func TestDispatcherFansOutToEverySink(t *testing.T) {
+	a := &recordingSink{}
+	b := &recordingSink{}
+	d := NewDispatcher([]Sink{a, b})
+
+	statsCh := make(chan Stats, 1)
+	logCh := make(chan LogEntry, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statsCh <- Stats{Tags: map[string]string{"app": "foo"}}
+	logCh <- LogEntry{Line: "hello"}
+	close(statsCh)
+	close(logCh)
+
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx, statsCh, logCh)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		a.mu.Lock()
+		ready := len(a.stats) == 1 && len(a.logs) == 1
+		a.mu.Unlock()
+		if ready {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	<-done
+
+	for _, s := range []*recordingSink{a, b} {
+		s.mu.Lock()
+		if len(s.stats) != 1 || s.stats[0].Tags["app"] != "foo" {
+			t.Errorf("expected sink to receive the stats sample, got %+v", s.stats)
+		}
+		if len(s.logs) != 1 || s.logs[0].Line != "hello" {
+			t.Errorf("expected sink to receive the log entry, got %+v", s.logs)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestNewSinksUnknownName(t *testing.T) {
+	if _, err := NewSinks("bogus"); err == nil {
+		t.Error("expected an error for an unknown sink name")
+	}
+}
+
+func TestCollectdInstanceAndSanitize(t *testing.T) {
+	instance := collectdInstance(map[string]string{"app": "my app", "task": "1", "device": "sda"})
+	if instance != "my_app.1.sda" {
+		t.Errorf("unexpected instance: %q", instance)
+	}
+}
+
+func TestStatsDTagsSortedAndStable(t *testing.T) {
+	tags := statsDTags(map[string]string{"task": "bar", "app": "foo"})
+	if tags != "app:foo,task:bar" {
+		t.Errorf("unexpected tags: %q", tags)
+	}
+}
+
+// TestPrometheusSinkEmitsEveryFanOutKindForOneContainer reproduces the
+// shape of a single container's interval batch: the untagged base
+// sample plus one per-device and one per-interface sample, as
+// expandStats would produce with the shipped defaults
+// (COLLECTD_DOCKER_PERDEVICE=1). Registering a GaugeVec a second time
+// with a different label set for an already-registered metric name
+// returns an error from familyFor/Register, so this fails loudly if
+// that regresses.
+func TestPrometheusSinkEmitsEveryFanOutKindForOneContainer(t *testing.T) {
+	sink, err := NewPrometheusSink("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start sink: %s", err)
+	}
+	defer sink.Close()
+
+	base := Stats{Tags: map[string]string{"app": "foo", "task": "bar"}, Stats: docker.Stats{}}
+	device := Stats{Tags: map[string]string{"app": "foo", "task": "bar", "device": "sda"}, Stats: docker.Stats{}}
+	iface := Stats{Tags: map[string]string{"app": "foo", "task": "bar", "interface": "eth0"}, Stats: docker.Stats{}}
+
+	for _, s := range []Stats{base, device, iface} {
+		if err := sink.Emit(s); err != nil {
+			t.Fatalf("unexpected error emitting tags %+v: %s", s.Tags, err)
+		}
+	}
+}
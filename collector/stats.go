@@ -0,0 +1,60 @@
+package collector
+
+import "github.com/fsouza/go-dockerclient"
+
+// Stats is a single metrics sample for a monitored container, carrying
+// the app/task/group tags derived from its labels and environment
+// alongside the raw docker.Stats payload. When perdevice fan-out is in
+// effect, Tags additionally carries a "device" or "interface" key and
+// Metrics() limits itself to the counters for that device/interface;
+// the untagged base sample carries the container-wide CPU/memory
+// numbers (and, with COLLECTD_DOCKER_TOTAL, summed blkio/network
+// totals) exactly once.
+type Stats struct {
+	Tags  map[string]string
+	Stats docker.Stats
+}
+
+var perDeviceStats = Getenv("COLLECTD_DOCKER_PERDEVICE", "1") == "1"
+var totalStats = Getenv("COLLECTD_DOCKER_TOTAL", "") == "1"
+
+// expandStats fans a single docker.Stats sample out into one or more
+// tagged Stats messages: an untagged base sample (always emitted) plus,
+// when perdevice reporting is enabled, one per block device (tagged
+// "device") and one per network interface (tagged "interface").
+// Defaults match Telegraf's docker input: perdevice on, total off.
+func expandStats(tags map[string]string, s *docker.Stats) []Stats {
+	out := []Stats{{Tags: tags, Stats: *s}}
+
+	if perDeviceStats {
+		for device := range blkioDevices(s) {
+			out = append(out, Stats{Tags: withTag(tags, "device", device), Stats: *s})
+		}
+		for iface := range s.Networks {
+			out = append(out, Stats{Tags: withTag(tags, "interface", iface), Stats: *s})
+		}
+	}
+
+	return out
+}
+
+// blkioDevices returns the set of device names (resolved via
+// resolveDevice) present in the container's per-device blkio counters.
+func blkioDevices(s *docker.Stats) map[string]bool {
+	devices := map[string]bool{}
+	for _, entry := range s.BlkioStats.IOServiceBytesRecursive {
+		devices[resolveDevice(entry.Major, entry.Minor)] = true
+	}
+	return devices
+}
+
+// withTag returns a copy of tags with key=value added, leaving the
+// original map (shared across every sample for this monitor) untouched.
+func withTag(tags map[string]string, key, value string) map[string]string {
+	out := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
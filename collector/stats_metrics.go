@@ -0,0 +1,111 @@
+package collector
+
+import "github.com/fsouza/go-dockerclient"
+
+// Metrics flattens a Stats sample into a name->value map. The schema
+// depends entirely on which fan-out tag the sample carries, and each
+// metric name is only ever emitted under one schema so sinks with a
+// fixed per-metric label set (e.g. Prometheus) never see it twice with
+// different tags: a sample tagged "device" or "interface" (see
+// expandStats) returns only that device's/interface's counters, and the
+// untagged base sample returns the container-wide CPU/memory metrics,
+// plus blkio/network totals summed across every device/interface when
+// COLLECTD_DOCKER_TOTAL is set.
+func (s Stats) Metrics() map[string]float64 {
+	if device, ok := s.Tags["device"]; ok {
+		read, write := blkioBytesForDevice(s.Stats, device)
+		return map[string]float64{
+			"blkio.read_bytes":  read,
+			"blkio.write_bytes": write,
+		}
+	}
+
+	if iface, ok := s.Tags["interface"]; ok {
+		net, ok := s.Stats.Networks[iface]
+		if !ok {
+			return map[string]float64{}
+		}
+		return map[string]float64{
+			"network.rx_bytes": float64(net.RxBytes),
+			"network.tx_bytes": float64(net.TxBytes),
+		}
+	}
+
+	m := map[string]float64{
+		"memory.usage": float64(s.Stats.MemoryStats.Usage),
+		"memory.limit": float64(s.Stats.MemoryStats.Limit),
+	}
+	if percent, ok := cpuUsagePercent(s.Stats); ok {
+		m["cpu.usage_percent"] = percent
+	}
+
+	if totalStats {
+		read, write := totalBlkioBytes(s.Stats)
+		m["blkio.read_bytes"] = read
+		m["blkio.write_bytes"] = write
+
+		rx, tx := totalNetworkBytes(s.Stats)
+		m["network.rx_bytes"] = rx
+		m["network.tx_bytes"] = tx
+	}
+
+	return m
+}
+
+// cpuUsagePercent computes the standard docker stats CPU percentage
+// from the delta between this sample and the previous one.
+func cpuUsagePercent(s docker.Stats) (float64, bool) {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemCPUUsage) - float64(s.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0, false
+	}
+
+	cpuCount := float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	if cpuCount == 0 {
+		cpuCount = 1
+	}
+
+	return (cpuDelta / systemDelta) * cpuCount * 100.0, true
+}
+
+// blkioBytesForDevice sums the Read/Write blkio byte counters for the
+// device previously resolved by resolveDevice.
+func blkioBytesForDevice(s docker.Stats, device string) (read, write float64) {
+	for _, entry := range s.BlkioStats.IOServiceBytesRecursive {
+		if resolveDevice(entry.Major, entry.Minor) != device {
+			continue
+		}
+		switch entry.Op {
+		case "Read":
+			read += float64(entry.Value)
+		case "Write":
+			write += float64(entry.Value)
+		}
+	}
+	return read, write
+}
+
+// totalBlkioBytes sums the Read/Write blkio byte counters across every
+// device, for the COLLECTD_DOCKER_TOTAL aggregate.
+func totalBlkioBytes(s docker.Stats) (read, write float64) {
+	for _, entry := range s.BlkioStats.IOServiceBytesRecursive {
+		switch entry.Op {
+		case "Read":
+			read += float64(entry.Value)
+		case "Write":
+			write += float64(entry.Value)
+		}
+	}
+	return read, write
+}
+
+// totalNetworkBytes sums the rx/tx byte counters across every network
+// interface, for the COLLECTD_DOCKER_TOTAL aggregate.
+func totalNetworkBytes(s docker.Stats) (rx, tx float64) {
+	for _, net := range s.Networks {
+		rx += float64(net.RxBytes)
+		tx += float64(net.TxBytes)
+	}
+	return rx, tx
+}
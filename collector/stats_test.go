@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+func TestExpandStatsPerDevice(t *testing.T) {
+	s := &docker.Stats{
+		Networks: map[string]docker.NetworkStats{
+			"eth0": {},
+			"eth1": {},
+		},
+	}
+	s.BlkioStats.IOServiceBytesRecursive = []docker.BlkioStatsEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 1},
+		{Major: 8, Minor: 16, Op: "Read", Value: 2},
+	}
+
+	out := expandStats(map[string]string{"app": "foo"}, s)
+
+	var base, devices, ifaces int
+	for _, stat := range out {
+		_, hasDevice := stat.Tags["device"]
+		_, hasIface := stat.Tags["interface"]
+		switch {
+		case hasDevice:
+			devices++
+		case hasIface:
+			ifaces++
+		default:
+			base++
+		}
+		if stat.Tags["app"] != "foo" {
+			t.Errorf("expected original tags to be preserved, got %+v", stat.Tags)
+		}
+	}
+
+	if base != 1 {
+		t.Errorf("expected exactly 1 untagged base sample, got %d", base)
+	}
+	if devices != 2 {
+		t.Errorf("expected 2 per-device samples, got %d", devices)
+	}
+	if ifaces != 2 {
+		t.Errorf("expected 2 per-interface samples, got %d", ifaces)
+	}
+}
+
+func TestMetricsDoesNotDuplicateContainerWideFieldsAcrossTagShapes(t *testing.T) {
+	s := docker.Stats{}
+	s.MemoryStats.Usage = 123
+
+	device := Stats{Tags: map[string]string{"device": "sda"}, Stats: s}
+	iface := Stats{Tags: map[string]string{"interface": "eth0"}, Stats: s}
+	base := Stats{Tags: map[string]string{}, Stats: s}
+
+	if _, ok := device.Metrics()["memory.usage"]; ok {
+		t.Error("expected per-device sample to not carry memory.usage")
+	}
+	if _, ok := iface.Metrics()["memory.usage"]; ok {
+		t.Error("expected per-interface sample to not carry memory.usage")
+	}
+	if v, ok := base.Metrics()["memory.usage"]; !ok || v != 123 {
+		t.Errorf("expected untagged base sample to carry memory.usage, got %v (present=%v)", v, ok)
+	}
+}
+
+func TestMetricsTotalAggregatesAcrossDevicesAndInterfaces(t *testing.T) {
+	totalStats = true
+	defer func() { totalStats = false }()
+
+	s := docker.Stats{
+		Networks: map[string]docker.NetworkStats{
+			"eth0": {RxBytes: 10, TxBytes: 1},
+			"eth1": {RxBytes: 20, TxBytes: 2},
+		},
+	}
+	s.BlkioStats.IOServiceBytesRecursive = []docker.BlkioStatsEntry{
+		{Major: 8, Minor: 0, Op: "Read", Value: 5},
+		{Major: 8, Minor: 16, Op: "Read", Value: 7},
+	}
+
+	base := Stats{Tags: map[string]string{}, Stats: s}
+	metrics := base.Metrics()
+
+	if metrics["blkio.read_bytes"] != 12 {
+		t.Errorf("expected aggregated blkio.read_bytes of 12, got %v", metrics["blkio.read_bytes"])
+	}
+	if metrics["network.rx_bytes"] != 30 {
+		t.Errorf("expected aggregated network.rx_bytes of 30, got %v", metrics["network.rx_bytes"])
+	}
+	if metrics["network.tx_bytes"] != 3 {
+		t.Errorf("expected aggregated network.tx_bytes of 3, got %v", metrics["network.tx_bytes"])
+	}
+}
+
+func TestWithTagDoesNotMutateOriginal(t *testing.T) {
+	original := map[string]string{"app": "foo"}
+	tagged := withTag(original, "device", "sda")
+
+	if _, ok := original["device"]; ok {
+		t.Error("withTag should not mutate the original map")
+	}
+	if tagged["device"] != "sda" || tagged["app"] != "foo" {
+		t.Errorf("unexpected tagged map: %+v", tagged)
+	}
+}
@@ -0,0 +1,203 @@
+package collector
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+// WatcherDockerClient is the subset of docker.Client used by Watcher.
+type WatcherDockerClient interface {
+	MonitorDockerClient
+	AddEventListener(listener chan<- *docker.APIEvents) error
+	RemoveEventListener(listener chan<- *docker.APIEvents) error
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+}
+
+const (
+	eventBackoffMin = 100 * time.Millisecond
+	eventBackoffMax = 30 * time.Second
+)
+
+// Watcher discovers containers by subscribing to the Docker daemon's
+// event stream instead of polling, so monitoring starts as soon as a
+// container is reported `start` rather than waiting for the next list
+// interval.
+type Watcher struct {
+	client   WatcherDockerClient
+	interval int
+
+	mu       sync.Mutex
+	monitors map[string]*monitorHandle
+}
+
+type monitorHandle struct {
+	monitor *Monitor
+}
+
+// NewWatcher creates a Watcher for the given docker client, using
+// interval as the per-monitor stat sampling interval.
+func NewWatcher(c WatcherDockerClient, interval int) *Watcher {
+	return &Watcher{
+		client:   c,
+		interval: interval,
+		monitors: map[string]*monitorHandle{},
+	}
+}
+
+// Run subscribes to the Docker event stream and feeds container stats
+// into ch until ctx is cancelled, then returns ctx.Err(). A failed
+// subscription or an ended event stream is retried, not returned.
+func (w *Watcher) Run(ctx context.Context, ch chan<- Stats) error {
+	defer w.stopAll()
+
+	backoff := eventBackoffMin
+	for {
+		events := make(chan *docker.APIEvents, 100)
+		if err := w.client.AddEventListener(events); err != nil {
+			log.Printf("Unable to add docker event listener: %s", err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > eventBackoffMax {
+				backoff = eventBackoffMax
+			}
+			continue
+		}
+		backoff = eventBackoffMin
+
+		w.resync(ch)
+		err := w.consume(ctx, events, ch)
+		w.client.RemoveEventListener(events)
+
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// event stream ended unexpectedly (e.g. daemon restart); reconnect
+	}
+}
+
+func (w *Watcher) consume(ctx context.Context, events <-chan *docker.APIEvents, ch chan<- Stats) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event, ch)
+		}
+	}
+}
+
+func (w *Watcher) handleEvent(event *docker.APIEvents, ch chan<- Stats) {
+	switch {
+	case event.Action == "start", strings.HasPrefix(event.Action, "health_status"):
+		w.startMonitor(event.Actor.ID, ch)
+	case event.Action == "die", event.Action == "destroy":
+		w.stopMonitor(event.Actor.ID)
+	}
+}
+
+// resync lists currently running containers and diffs them against the
+// set of monitors we already have, so a reconnect after a daemon
+// restart (or a listener gap) doesn't leave containers unmonitored.
+func (w *Watcher) resync(ch chan<- Stats) {
+	containers, err := w.client.ListContainers(docker.ListContainersOptions{})
+	if err != nil {
+		log.Printf("Unable to list containers for resync: %s", err)
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, c := range containers {
+		seen[c.ID] = true
+		if !defaultFilter.AllowedAPIContainer(c) {
+			continue
+		}
+		w.startMonitor(c.ID, ch)
+	}
+
+	w.mu.Lock()
+	var stale []string
+	for id := range w.monitors {
+		if !seen[id] {
+			stale = append(stale, id)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, id := range stale {
+		w.stopMonitor(id)
+	}
+}
+
+func (w *Watcher) startMonitor(id string, ch chan<- Stats) {
+	w.mu.Lock()
+	if _, ok := w.monitors[id]; ok {
+		w.mu.Unlock()
+		return
+	}
+	w.mu.Unlock()
+
+	monitor, err := NewMonitor(w.client, id, w.interval)
+	if err != nil {
+		if err != ErrNoNeedToMonitor {
+			log.Printf("Unable to monitor %s: %s", id, err)
+		}
+		return
+	}
+
+	w.mu.Lock()
+	if _, ok := w.monitors[id]; ok {
+		w.mu.Unlock()
+		monitor.Stop()
+		return
+	}
+	w.monitors[id] = &monitorHandle{monitor: monitor}
+	w.mu.Unlock()
+
+	go func() {
+		if err := monitor.handle(ch); err != nil {
+			log.Printf("Stats stream for %s ended: %s", id, err)
+		}
+		w.mu.Lock()
+		delete(w.monitors, id)
+		w.mu.Unlock()
+	}()
+}
+
+func (w *Watcher) stopMonitor(id string) {
+	w.mu.Lock()
+	h, ok := w.monitors[id]
+	if ok {
+		delete(w.monitors, id)
+	}
+	w.mu.Unlock()
+
+	if ok {
+		h.monitor.Stop()
+	}
+}
+
+func (w *Watcher) stopAll() {
+	w.mu.Lock()
+	handles := w.monitors
+	w.monitors = map[string]*monitorHandle{}
+	w.mu.Unlock()
+
+	for _, h := range handles {
+		h.monitor.Stop()
+	}
+}
@@ -0,0 +1,170 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+)
+
+type fakeWatcherClient struct {
+	mu         sync.Mutex
+	containers map[string]*docker.Container
+	running    []docker.APIContainers
+}
+
+func (f *fakeWatcherClient) InspectContainer(id string) (*docker.Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, ok := f.containers[id]
+	if !ok {
+		return nil, fmt.Errorf("no such container: %s", id)
+	}
+	return c, nil
+}
+
+func (f *fakeWatcherClient) Stats(opts docker.StatsOptions) error {
+	<-opts.Done
+	return nil
+}
+
+func (f *fakeWatcherClient) AddEventListener(chan<- *docker.APIEvents) error    { return nil }
+func (f *fakeWatcherClient) RemoveEventListener(chan<- *docker.APIEvents) error { return nil }
+
+func (f *fakeWatcherClient) ListContainers(docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.running, nil
+}
+
+func monitoredContainer(id string) *docker.Container {
+	return &docker.Container{
+		ID:     id,
+		Name:   "/" + id,
+		Config: &docker.Config{Labels: map[string]string{"app_id": "/myapp"}},
+	}
+}
+
+func waitForMonitorCount(t *testing.T, w *Watcher, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		w.mu.Lock()
+		count := len(w.monitors)
+		w.mu.Unlock()
+		if count == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d monitors, timed out waiting", n)
+}
+
+func TestWatcherHandleEventStartAndDie(t *testing.T) {
+	client := &fakeWatcherClient{containers: map[string]*docker.Container{
+		"c1": monitoredContainer("c1"),
+	}}
+	w := NewWatcher(client, 1)
+	ch := make(chan Stats, 10)
+
+	w.handleEvent(&docker.APIEvents{Action: "start", Actor: docker.APIActor{ID: "c1"}}, ch)
+	waitForMonitorCount(t, w, 1)
+
+	w.handleEvent(&docker.APIEvents{Action: "die", Actor: docker.APIActor{ID: "c1"}}, ch)
+	waitForMonitorCount(t, w, 0)
+}
+
+func TestWatcherHandleEventDestroy(t *testing.T) {
+	client := &fakeWatcherClient{containers: map[string]*docker.Container{
+		"c1": monitoredContainer("c1"),
+	}}
+	w := NewWatcher(client, 1)
+	ch := make(chan Stats, 10)
+
+	w.handleEvent(&docker.APIEvents{Action: "start", Actor: docker.APIActor{ID: "c1"}}, ch)
+	waitForMonitorCount(t, w, 1)
+
+	w.handleEvent(&docker.APIEvents{Action: "destroy", Actor: docker.APIActor{ID: "c1"}}, ch)
+	waitForMonitorCount(t, w, 0)
+}
+
+func TestWatcherHandleEventHealthStatusVariants(t *testing.T) {
+	healthActions := []string{
+		"health_status: healthy",
+		"health_status: unhealthy",
+		"health_status: starting",
+		"health_status",
+	}
+
+	for _, action := range healthActions {
+		t.Run(action, func(t *testing.T) {
+			client := &fakeWatcherClient{containers: map[string]*docker.Container{
+				"c1": monitoredContainer("c1"),
+			}}
+			w := NewWatcher(client, 1)
+			ch := make(chan Stats, 10)
+
+			w.handleEvent(&docker.APIEvents{Action: action, Actor: docker.APIActor{ID: "c1"}}, ch)
+			waitForMonitorCount(t, w, 1)
+		})
+	}
+}
+
+func TestWatcherHandleEventIgnoresUnrelatedActions(t *testing.T) {
+	client := &fakeWatcherClient{containers: map[string]*docker.Container{
+		"c1": monitoredContainer("c1"),
+	}}
+	w := NewWatcher(client, 1)
+	ch := make(chan Stats, 10)
+
+	w.handleEvent(&docker.APIEvents{Action: "exec_create", Actor: docker.APIActor{ID: "c1"}}, ch)
+
+	w.mu.Lock()
+	count := len(w.monitors)
+	w.mu.Unlock()
+	if count != 0 {
+		t.Errorf("expected exec_create to be ignored, got %d monitors", count)
+	}
+}
+
+func TestWatcherResyncStopsStaleMonitors(t *testing.T) {
+	client := &fakeWatcherClient{
+		containers: map[string]*docker.Container{
+			"c1": monitoredContainer("c1"),
+			"c2": monitoredContainer("c2"),
+		},
+		running: []docker.APIContainers{{ID: "c1", Names: []string{"/c1"}}},
+	}
+	w := NewWatcher(client, 1)
+	ch := make(chan Stats, 10)
+
+	w.startMonitor("c1", ch)
+	w.startMonitor("c2", ch)
+	waitForMonitorCount(t, w, 2)
+
+	w.resync(ch)
+	waitForMonitorCount(t, w, 1)
+
+	w.mu.Lock()
+	_, stillMonitored := w.monitors["c1"]
+	w.mu.Unlock()
+	if !stillMonitored {
+		t.Error("expected c1, which is still running, to remain monitored")
+	}
+}
+
+func TestWatcherResyncStartsNewlyRunningContainers(t *testing.T) {
+	client := &fakeWatcherClient{
+		containers: map[string]*docker.Container{
+			"c1": monitoredContainer("c1"),
+		},
+		running: []docker.APIContainers{{ID: "c1", Names: []string{"/c1"}}},
+	}
+	w := NewWatcher(client, 1)
+	ch := make(chan Stats, 10)
+
+	w.resync(ch)
+	waitForMonitorCount(t, w, 1)
+}